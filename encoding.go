@@ -0,0 +1,154 @@
+package nbt2json
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// Encoding describes how a stream of NBT bytes lays out integers, string
+// lengths, and list/array lengths. Java Edition always uses fixed-width values
+// in a single byte order; Minecraft Bedrock Edition additionally has two
+// varint-based variants: "varint", used in Bedrock's own disk saves for
+// actors/entities, zigzag-varint encodes TAG_Int and TAG_Long payloads and
+// unsigned-varint encodes string lengths, and "network-varint", used on the
+// Bedrock wire protocol, does the same plus zigzag-varint encodes every
+// TAG_List/array length as well.
+type Encoding struct {
+	byteOrder          binary.ByteOrder
+	varintInts         bool
+	varintStringLen    bool
+	varintContainerLen bool
+}
+
+var (
+	// EncodingJavaBig is big-endian Java Edition NBT: the format used by Java
+	// Edition save files and, historically, the only encoding this package
+	// supported.
+	EncodingJavaBig = Encoding{byteOrder: binary.BigEndian}
+	// EncodingJavaLittle is little-endian Java Edition NBT, used by some
+	// tools and by Java Edition's level.dat header.
+	EncodingJavaLittle = Encoding{byteOrder: binary.LittleEndian}
+	// EncodingBedrockVarint is Bedrock Edition's disk-save encoding: little-endian,
+	// zigzag-varint ints/longs, unsigned-varint string lengths.
+	EncodingBedrockVarint = Encoding{byteOrder: binary.LittleEndian, varintInts: true, varintStringLen: true}
+	// EncodingBedrockNetwork is Bedrock Edition's network encoding: everything
+	// EncodingBedrockVarint has, plus zigzag-varint list and array lengths.
+	EncodingBedrockNetwork = Encoding{byteOrder: binary.LittleEndian, varintInts: true, varintStringLen: true, varintContainerLen: true}
+)
+
+// FromByteOrder wraps a plain binary.ByteOrder, as accepted by Json2Nbt and
+// Yaml2Nbt, as the equivalent fixed-width Java Edition Encoding.
+func FromByteOrder(order binary.ByteOrder) Encoding {
+	return Encoding{byteOrder: order}
+}
+
+// byteReader adapts an io.Reader to io.ByteReader one byte at a time, for use
+// with binary.ReadVarint/ReadUvarint. It does not buffer or over-read, so it
+// is safe to wrap a reader that will go on to be used for other reads.
+type byteReader struct{ r io.Reader }
+
+func (b byteReader) ReadByte() (byte, error) {
+	var buf [1]byte
+	_, err := io.ReadFull(b.r, buf[:])
+	return buf[0], err
+}
+
+func writeVarint(w io.Writer, n int64) error {
+	var buf [binary.MaxVarintLen64]byte
+	size := binary.PutVarint(buf[:], n)
+	_, err := w.Write(buf[:size])
+	return err
+}
+
+func readVarint(r io.Reader) (int64, error) {
+	return binary.ReadVarint(byteReader{r})
+}
+
+func writeUvarint(w io.Writer, n uint64) error {
+	var buf [binary.MaxVarintLen64]byte
+	size := binary.PutUvarint(buf[:], n)
+	_, err := w.Write(buf[:size])
+	return err
+}
+
+func readUvarint(r io.Reader) (uint64, error) {
+	return binary.ReadUvarint(byteReader{r})
+}
+
+// writeStringLen writes a TAG_String length, which Bedrock encodes as an
+// unsigned varint and Java encodes as a fixed int16.
+func writeStringLen(w io.Writer, enc Encoding, n int) error {
+	if enc.varintStringLen {
+		return writeUvarint(w, uint64(n))
+	}
+	return binary.Write(w, enc.byteOrder, int16(n))
+}
+
+func readStringLen(r io.Reader, enc Encoding) (int, error) {
+	if enc.varintStringLen {
+		n, err := readUvarint(r)
+		return int(n), err
+	}
+	var n int16
+	err := binary.Read(r, enc.byteOrder, &n)
+	return int(n), err
+}
+
+// writeContainerLen writes a TAG_List/TAG_Byte_Array/TAG_Int_Array/TAG_Long_Array
+// length, which only the Bedrock network encoding varint-encodes; every other
+// encoding, including Bedrock's own disk-save "varint" encoding, uses a fixed
+// int32. The network encoding's length is a signed, zigzag-varint TAG_Int
+// (like writeTagInt), not an unsigned varint.
+func writeContainerLen(w io.Writer, enc Encoding, n int) error {
+	if enc.varintContainerLen {
+		return writeVarint(w, int64(n))
+	}
+	return binary.Write(w, enc.byteOrder, int32(n))
+}
+
+func readContainerLen(r io.Reader, enc Encoding) (int, error) {
+	if enc.varintContainerLen {
+		n, err := readVarint(r)
+		return int(n), err
+	}
+	var n int32
+	err := binary.Read(r, enc.byteOrder, &n)
+	return int(n), err
+}
+
+// writeTagInt writes a TAG_Int payload, zigzag-varint encoded on both Bedrock
+// encodings and fixed-width on Java Edition.
+func writeTagInt(w io.Writer, enc Encoding, v int32) error {
+	if enc.varintInts {
+		return writeVarint(w, int64(v))
+	}
+	return binary.Write(w, enc.byteOrder, v)
+}
+
+func readTagInt(r io.Reader, enc Encoding) (int32, error) {
+	if enc.varintInts {
+		n, err := readVarint(r)
+		return int32(n), err
+	}
+	var v int32
+	err := binary.Read(r, enc.byteOrder, &v)
+	return v, err
+}
+
+// writeTagLong writes a TAG_Long payload, zigzag-varint encoded on both
+// Bedrock encodings and fixed-width on Java Edition.
+func writeTagLong(w io.Writer, enc Encoding, v int64) error {
+	if enc.varintInts {
+		return writeVarint(w, v)
+	}
+	return binary.Write(w, enc.byteOrder, v)
+}
+
+func readTagLong(r io.Reader, enc Encoding) (int64, error) {
+	if enc.varintInts {
+		return readVarint(r)
+	}
+	var v int64
+	err := binary.Read(r, enc.byteOrder, &v)
+	return v, err
+}
@@ -41,6 +41,13 @@ func Yaml2Nbt(b []byte, byteOrder binary.ByteOrder) ([]byte, error) {
 
 // Json2Nbt converts JSON byte array to uncompressed NBT byte array
 func Json2Nbt(b []byte, byteOrder binary.ByteOrder) ([]byte, error) {
+	return Json2NbtEncoding(b, FromByteOrder(byteOrder))
+}
+
+// Json2NbtEncoding is Json2Nbt for callers who need a Bedrock Edition encoding
+// (EncodingBedrockVarint, EncodingBedrockNetwork) rather than a plain
+// binary.ByteOrder.
+func Json2NbtEncoding(b []byte, enc Encoding) ([]byte, error) {
 	nbtOut := new(bytes.Buffer)
 	var nbtJsonData NbtJson
 	var nbtTag interface{}
@@ -63,7 +70,7 @@ func Json2Nbt(b []byte, byteOrder binary.ByteOrder) ([]byte, error) {
 		return nil, JsonParseError{"Error unmarshalling nbt: value", err}
 	}
 	for _, nbtTag = range nbtArray {
-		err = writeTag(nbtOut, byteOrder, nbtTag)
+		err = writeTag(nbtOut, enc, nbtTag)
 		if err != nil {
 			return nil, err
 		}
@@ -72,7 +79,23 @@ func Json2Nbt(b []byte, byteOrder binary.ByteOrder) ([]byte, error) {
 	return nbtOut.Bytes(), nil
 }
 
-func writeTag(w io.Writer, byteOrder binary.ByteOrder, myMap interface{}) error {
+// longFromValue extracts an int64 from a TAG_Long/TAG_Long_Array JSON value,
+// accepting both the default json.Number form and the string form Nbt2Json
+// emits when Options.PreserveNumbers is set.
+func longFromValue(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func writeTag(w io.Writer, enc Encoding, myMap interface{}) error {
 	var err error
 	if m, ok := myMap.(map[string]interface{}); ok {
 		i64, err := m["tagType"].(json.Number).Int64()
@@ -82,23 +105,23 @@ func writeTag(w io.Writer, byteOrder binary.ByteOrder, myMap interface{}) error
 				// not expecting a 0 tag, but if it occurs just ignore it
 				return nil
 			}
-			err = binary.Write(w, byteOrder, byte(tagType))
+			err = binary.Write(w, enc.byteOrder, byte(tagType))
 			if err != nil {
 				return JsonParseError{"Error writing tagType" + string(tagType), err}
 			}
 			if name, ok := m["name"].(string); ok {
-				err = binary.Write(w, byteOrder, int16(len(name)))
+				err = writeStringLen(w, enc, len(name))
 				if err != nil {
 					return JsonParseError{"Error writing name length", err}
 				}
-				err = binary.Write(w, byteOrder, []byte(name))
+				err = binary.Write(w, enc.byteOrder, []byte(name))
 				if err != nil {
 					return JsonParseError{"Error converting name", err}
 				}
 			} else {
 				return JsonParseError{"name field not a string", err}
 			}
-			err = writePayload(w, byteOrder, m, tagType)
+			err = writePayload(w, enc, m, tagType)
 			if err != nil {
 				return err
 			}
@@ -112,14 +135,14 @@ func writeTag(w io.Writer, byteOrder binary.ByteOrder, myMap interface{}) error
 	return err
 }
 
-func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interface{}, tagType byte) error {
+func writePayload(w io.Writer, enc Encoding, m map[string]interface{}, tagType byte) error {
 	var err error
 
 	switch tagType {
 	case 1: // TAG_Byte
 		i, err := m["value"].(json.Number).Int64()
 		if err == nil {
-			err = binary.Write(w, byteOrder, int8(i))
+			err = binary.Write(w, enc.byteOrder, int8(i))
 			if err != nil {
 				return JsonParseError{"Error writing byte payload", err}
 			}
@@ -129,7 +152,7 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 	case 2: // TAG_Short
 		i, err := m["value"].(json.Number).Int64()
 		if err == nil {
-			err = binary.Write(w, byteOrder, int16(i))
+			err = binary.Write(w, enc.byteOrder, int16(i))
 			if err != nil {
 				return JsonParseError{"Error writing short payload", err}
 			}
@@ -139,7 +162,7 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 	case 3: // TAG_Int
 		i, err := m["value"].(json.Number).Int64()
 		if err == nil {
-			err = binary.Write(w, byteOrder, int32(i))
+			err = writeTagInt(w, enc, int32(i))
 			if err != nil {
 				return JsonParseError{"Error writing int32 payload", err}
 			}
@@ -147,19 +170,19 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 			return JsonParseError{"Tag Int value field not a number", err}
 		}
 	case 4: // TAG_Long
-		i, err := m["value"].(json.Number).Int64()
-		if err == nil {
-			err = binary.Write(w, byteOrder, int64(i))
+		i, ok := longFromValue(m["value"])
+		if ok {
+			err = writeTagLong(w, enc, i)
 			if err != nil {
 				return JsonParseError{"Error writing int64 payload", err}
 			}
 		} else {
-			return JsonParseError{"Tag Long value field not a number", err}
+			return JsonParseError{"Tag Long value field not a number", nil}
 		}
 	case 5: // TAG_Float
 		f, err := m["value"].(json.Number).Float64()
 		if err == nil {
-			err = binary.Write(w, byteOrder, float32(f))
+			err = binary.Write(w, enc.byteOrder, float32(f))
 			if err != nil {
 				return JsonParseError{"Error writing float32 payload", err}
 			}
@@ -169,14 +192,14 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 	case 6: // TAG_Double
 		f, err := m["value"].(json.Number).Float64()
 		if err == nil {
-			err = binary.Write(w, byteOrder, f)
+			err = binary.Write(w, enc.byteOrder, f)
 			if err != nil {
 				return JsonParseError{"Tag Double - Error writing float64 payload", err}
 			}
 		} else {
 			// return JsonParseError{"Tag Byte value field not a number", err}
 			f = math.NaN()
-			err = binary.Write(w, byteOrder, f)
+			err = binary.Write(w, enc.byteOrder, f)
 			if err != nil {
 				return JsonParseError{"Tag Double - Error writing float64 payload", err}
 			}
@@ -184,14 +207,14 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 		}
 	case 7: // TAG_Byte_Array
 		if values, ok := m["value"].([]interface{}); ok {
-			err = binary.Write(w, byteOrder, int32(len(values)))
+			err = writeContainerLen(w, enc, len(values))
 			if err != nil {
 				return JsonParseError{"Error writing byte array length", err}
 			}
 			for _, value := range values {
 				i, err := value.(json.Number).Int64()
 				if err == nil {
-					err = binary.Write(w, byteOrder, int8(i))
+					err = binary.Write(w, enc.byteOrder, int8(i))
 					if err != nil {
 						return JsonParseError{"Error writing element of byte array", err}
 					}
@@ -204,11 +227,11 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 		}
 	case 8: // TAG_String
 		if s, ok := m["value"].(string); ok {
-			err = binary.Write(w, byteOrder, int16(len([]byte(s))))
+			err = writeStringLen(w, enc, len([]byte(s)))
 			if err != nil {
 				return JsonParseError{"Error writing string length", err}
 			}
-			err = binary.Write(w, byteOrder, []byte(s))
+			err = binary.Write(w, enc.byteOrder, []byte(s))
 			if err != nil {
 				return JsonParseError{"Error writing string payload", err}
 			}
@@ -223,27 +246,27 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 			i64, err := listMap["tagListType"].(json.Number).Int64()
 			tagListType = byte(i64)
 			if err == nil {
-				err = binary.Write(w, byteOrder, byte(tagListType))
+				err = binary.Write(w, enc.byteOrder, byte(tagListType))
 				if err != nil {
 					return JsonParseError{"While writing tag list type", err}
 				}
 			}
 			if values, ok := listMap["list"].([]interface{}); ok {
-				err = binary.Write(w, byteOrder, int32(len(values)))
+				err = writeContainerLen(w, enc, len(values))
 				if err != nil {
 					return JsonParseError{"While writing tag list size", err}
 				}
 				for _, value := range values {
 					fakeTag := make(map[string]interface{})
 					fakeTag["value"] = value
-					err = writePayload(w, byteOrder, fakeTag, tagListType)
+					err = writePayload(w, enc, fakeTag, tagListType)
 					if err != nil {
 						return JsonParseError{"While writing tag list of type " + strconv.Itoa(int(tagListType)), err}
 					}
 				}
 			} else if listMap["list"] == nil {
 				// NBT lists can be null / nil and therefore aren't represented as an array in JSON
-				err = binary.Write(w, byteOrder, int32(0))
+				err = writeContainerLen(w, enc, 0)
 				if err != nil {
 					return JsonParseError{"While writing tag list null size", err}
 				}
@@ -258,13 +281,13 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 	case 10: // TAG_Compound
 		if values, ok := m["value"].([]interface{}); ok {
 			for _, value := range values {
-				err = writeTag(w, byteOrder, value)
+				err = writeTag(w, enc, value)
 				if err != nil {
 					return JsonParseError{"While writing Compound tags", err}
 				}
 			}
 			// write the end tag which is just a single byte 0
-			err = binary.Write(w, byteOrder, byte(0))
+			err = binary.Write(w, enc.byteOrder, byte(0))
 			if err != nil {
 				return JsonParseError{"Writing End tag", err}
 			}
@@ -273,18 +296,22 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 		}
 	case 11: // TAG_Int_Array
 		if values, ok := m["value"].([]interface{}); ok {
-			err = binary.Write(w, byteOrder, int32(len(values)))
+			err = writeContainerLen(w, enc, len(values))
 			if err != nil {
 				return JsonParseError{"Error writing int32 array length", err}
 			}
 			for _, value := range values {
-				if i, ok := value.(float64); ok {
-					err = binary.Write(w, byteOrder, int32(i))
-					if err != nil {
-						return JsonParseError{"Error writing element of int32 array", err}
-					}
-				} else {
-					return JsonParseError{"Tag Int value field not a number", err}
+				num, ok := value.(json.Number)
+				if !ok {
+					return JsonParseError{"Tag Int Array element not a number", nil}
+				}
+				i, err := num.Int64()
+				if err != nil {
+					return JsonParseError{"Tag Int Array element not a number", err}
+				}
+				err = writeTagInt(w, enc, int32(i))
+				if err != nil {
+					return JsonParseError{"Error writing element of int32 array", err}
 				}
 			}
 		} else {
@@ -292,18 +319,18 @@ func writePayload(w io.Writer, byteOrder binary.ByteOrder, m map[string]interfac
 		}
 	case 12: // TAG_Long_Array
 		if values, ok := m["value"].([]interface{}); ok {
-			err = binary.Write(w, byteOrder, int64(len(values)))
+			err = writeContainerLen(w, enc, len(values))
 			if err != nil {
 				return JsonParseError{"Error writing int64 array length", err}
 			}
 			for _, value := range values {
-				if i, ok := value.(float64); ok {
-					err = binary.Write(w, byteOrder, int64(i))
-					if err != nil {
-						return JsonParseError{"Error writing element of int64 array", err}
-					}
-				} else {
-					return JsonParseError{"Tag Int value field not a number", err}
+				i, ok := longFromValue(value)
+				if !ok {
+					return JsonParseError{"Tag Long Array element not a number", nil}
+				}
+				err = writeTagLong(w, enc, i)
+				if err != nil {
+					return JsonParseError{"Error writing element of int64 array", err}
 				}
 			}
 		} else {
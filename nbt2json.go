@@ -0,0 +1,201 @@
+package nbt2json
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Options controls how Nbt2Json renders values that would otherwise lose
+// precision once they pass through a JSON number, such as a TAG_Long or
+// TAG_Long_Array larger than 2^53, or most consumers' JSON parsers, which
+// read every JSON number as a float64.
+type Options struct {
+	// PreserveNumbers makes longs round-trip exactly: TAG_Long and
+	// TAG_Long_Array values are emitted as JSON strings (tagType already says
+	// "this is a long", so a string is unambiguous), and TAG_Double values use
+	// strconv's shortest round-trip formatting rather than encoding/json's
+	// default float64 handling.
+	PreserveNumbers bool
+}
+
+// NbtJson is the {"nbt":[...]} envelope Nbt2Json produces and Json2Nbt reads.
+type NbtJson struct {
+	Nbt []NbtTag `json:"nbt"`
+}
+
+// NbtTag is one entry of NbtJson.Nbt: a tagType/name/value triple mirroring
+// tagToMap's output. Value is left as interface{} since its shape depends on
+// TagType (scalar, array, {tagListType,list}, or an array of tag objects for
+// TAG_Compound).
+type NbtTag struct {
+	TagType int         `json:"tagType"`
+	Name    string      `json:"name"`
+	Value   interface{} `json:"value"`
+}
+
+// Nbt2Json converts an uncompressed NBT byte array to the
+// {"nbt":[{tagType,name,value,...}]} JSON form Json2Nbt reads.
+func Nbt2Json(b []byte, byteOrder binary.ByteOrder) ([]byte, error) {
+	return Nbt2JsonOptions(b, byteOrder, Options{})
+}
+
+// Nbt2JsonOptions is Nbt2Json with Options controlling number fidelity.
+func Nbt2JsonOptions(b []byte, byteOrder binary.ByteOrder, opts Options) ([]byte, error) {
+	return Nbt2JsonEncoding(b, FromByteOrder(byteOrder), opts)
+}
+
+// Nbt2JsonEncoding is Nbt2Json for callers who need a Bedrock Edition encoding
+// (EncodingBedrockVarint, EncodingBedrockNetwork) rather than a plain
+// binary.ByteOrder.
+func Nbt2JsonEncoding(b []byte, enc Encoding, opts Options) ([]byte, error) {
+	var tag Tag
+	if err := NewDecoder(bytes.NewReader(b), enc).Decode(&tag); err != nil {
+		return nil, JsonParseError{"Error decoding NBT", err}
+	}
+	m, err := tagToMap(tag, opts)
+	if err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(struct {
+		Nbt []interface{} `json:"nbt"`
+	}{Nbt: []interface{}{m}})
+	if err != nil {
+		return nil, JsonParseError{"Error marshalling nbt to JSON", err}
+	}
+	return out, nil
+}
+
+// tagToMap converts one tag, including its name and tagType, to the
+// map[string]interface{} shape writeTag/writePayload expect as input.
+func tagToMap(tag Tag, opts Options) (map[string]interface{}, error) {
+	m := map[string]interface{}{
+		"tagType": int(tag.TagType),
+		"name":    tag.Name,
+	}
+	switch tag.TagType {
+	case 9: // TAG_List
+		list := make([]interface{}, len(tag.List))
+		for i, child := range tag.List {
+			v, err := listElementToJSON(child, opts)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		m["value"] = map[string]interface{}{
+			"tagListType": int(tag.TagListType),
+			"list":        list,
+		}
+	case 10: // TAG_Compound
+		children := make([]interface{}, len(tag.Compound))
+		for i, child := range tag.Compound {
+			cm, err := tagToMap(child, opts)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = cm
+		}
+		m["value"] = children
+	default:
+		v, err := tagValueToJSON(tag, opts)
+		if err != nil {
+			return nil, err
+		}
+		m["value"] = v
+	}
+	return m, nil
+}
+
+// listElementToJSON converts one element of a TAG_List to JSON. List
+// elements carry no name or tagType of their own in this schema (the
+// enclosing list's tagListType already says what they are), so a compound
+// element is a bare array of child tag maps and a nested list element is a
+// bare {tagListType, list} object, mirroring how writePayload's TAG_List case
+// reads them back.
+func listElementToJSON(child Tag, opts Options) (interface{}, error) {
+	switch child.TagType {
+	case 10: // TAG_Compound
+		out := make([]interface{}, len(child.Compound))
+		for i, c := range child.Compound {
+			cm, err := tagToMap(c, opts)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = cm
+		}
+		return out, nil
+	case 9: // TAG_List
+		list := make([]interface{}, len(child.List))
+		for i, c := range child.List {
+			v, err := listElementToJSON(c, opts)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = v
+		}
+		return map[string]interface{}{
+			"tagListType": int(child.TagListType),
+			"list":        list,
+		}, nil
+	default:
+		return tagValueToJSON(child, opts)
+	}
+}
+
+// tagValueToJSON converts a scalar or array tag's payload to the JSON value
+// writePayload's "value" field expects.
+func tagValueToJSON(tag Tag, opts Options) (interface{}, error) {
+	switch tag.TagType {
+	case 1: // TAG_Byte
+		return int64(tag.Value.(int8)), nil
+	case 2: // TAG_Short
+		return int64(tag.Value.(int16)), nil
+	case 3: // TAG_Int
+		return int64(tag.Value.(int32)), nil
+	case 4: // TAG_Long
+		v := tag.Value.(int64)
+		if opts.PreserveNumbers {
+			return strconv.FormatInt(v, 10), nil
+		}
+		return v, nil
+	case 5: // TAG_Float
+		return float64(tag.Value.(float32)), nil
+	case 6: // TAG_Double
+		v := tag.Value.(float64)
+		if opts.PreserveNumbers {
+			return json.Number(strconv.AppendFloat(nil, v, 'g', -1, 64)), nil
+		}
+		return v, nil
+	case 7: // TAG_Byte_Array
+		src := tag.Value.([]int8)
+		out := make([]int64, len(src))
+		for i, n := range src {
+			out[i] = int64(n)
+		}
+		return out, nil
+	case 8: // TAG_String
+		return tag.Value.(string), nil
+	case 11: // TAG_Int_Array
+		src := tag.Value.([]int32)
+		out := make([]int64, len(src))
+		for i, n := range src {
+			out[i] = int64(n)
+		}
+		return out, nil
+	case 12: // TAG_Long_Array
+		src := tag.Value.([]int64)
+		if opts.PreserveNumbers {
+			out := make([]string, len(src))
+			for i, n := range src {
+				out[i] = strconv.FormatInt(n, 10)
+			}
+			return out, nil
+		}
+		return src, nil
+	default:
+		return nil, JsonParseError{fmt.Sprintf("tagValueToJSON: unexpected tagType %d", tag.TagType), nil}
+	}
+}
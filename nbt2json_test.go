@@ -0,0 +1,37 @@
+package nbt2json
+
+import "testing"
+
+func TestPreserveNumbersLongRoundTrip(t *testing.T) {
+	type Data struct {
+		Big int64
+		Arr []int64
+	}
+	in := Data{Big: 7205759403792793601, Arr: []int64{7205759403792793601, -1}}
+
+	nbtBytes, err := Marshal(in, EncodingJavaBig)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	j, err := Nbt2JsonEncoding(nbtBytes, EncodingJavaBig, Options{PreserveNumbers: true})
+	if err != nil {
+		t.Fatalf("Nbt2JsonEncoding: %v", err)
+	}
+
+	nbtBytes2, err := Json2NbtEncoding(j, EncodingJavaBig)
+	if err != nil {
+		t.Fatalf("Json2NbtEncoding: %v", err)
+	}
+
+	var out Data
+	if err := Unmarshal(nbtBytes2, &out, EncodingJavaBig); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if out.Big != in.Big {
+		t.Fatalf("Big round-trip mismatch: got %d, want %d", out.Big, in.Big)
+	}
+	if len(out.Arr) != len(in.Arr) || out.Arr[0] != in.Arr[0] || out.Arr[1] != in.Arr[1] {
+		t.Fatalf("Arr round-trip mismatch: got %v, want %v", out.Arr, in.Arr)
+	}
+}
@@ -0,0 +1,399 @@
+package nbt2json
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Marshal and Unmarshal let callers work with typed Go structs instead of the
+// untyped map[string]interface{} shape Json2Nbt works with, in the same spirit as
+// encoding/json. Struct fields are mapped to NBT tags using a `nbt:"..."` tag,
+// e.g.:
+//
+//	type PlayerData struct {
+//		Name   string  `nbt:"Name"`
+//		Health float32 `nbt:"Health"`
+//		Pos    []float64
+//	}
+//
+// The tag's first comma-separated part overrides the tag name (defaulting to
+// the field name); "-" skips the field entirely. Remaining parts are options:
+// "omitempty" skips zero-value fields on Marshal, "list" forces a []byte,
+// []int32, or []int64 field to encode as a regular TAG_List instead of the
+// compact *_Array tag, and "time=unix", "time=unixmilli", or "time=rfc3339"
+// selects how a time.Time field is represented (TAG_Long unix seconds/millis,
+// or TAG_String RFC 3339, respectively; "unix" is the default for time.Time).
+var timeType = reflect.TypeOf(time.Time{})
+
+// fieldOptions is the parsed form of one field's `nbt` struct tag.
+type fieldOptions struct {
+	name      string
+	omitempty bool
+	asList    bool
+	timeMode  string
+	skip      bool
+}
+
+func parseFieldTag(tag reflect.StructTag, fieldName string) fieldOptions {
+	opts := fieldOptions{name: fieldName, timeMode: "unix"}
+	raw, ok := tag.Lookup("nbt")
+	if !ok {
+		return opts
+	}
+	parts := strings.Split(raw, ",")
+	if parts[0] == "-" {
+		opts.skip = true
+		return opts
+	}
+	if parts[0] != "" {
+		opts.name = parts[0]
+	}
+	for _, p := range parts[1:] {
+		switch {
+		case p == "omitempty":
+			opts.omitempty = true
+		case p == "list":
+			opts.asList = true
+		case strings.HasPrefix(p, "time="):
+			opts.timeMode = strings.TrimPrefix(p, "time=")
+		}
+	}
+	return opts
+}
+
+// Marshal converts v, which must be a struct or a pointer to one, to an
+// uncompressed NBT byte array laid out per the given Encoding.
+func Marshal(v interface{}, encoding Encoding) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, JsonParseError{"Marshal: v is a nil pointer", nil}
+		}
+		rv = rv.Elem()
+	}
+	tag, err := marshalValue(rv, fieldOptions{name: "", timeMode: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	buf := new(bytes.Buffer)
+	if err = NewEncoder(buf, encoding).Encode(tag); err != nil {
+		return nil, JsonParseError{"Error encoding NBT from struct", err}
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalValue converts one Go value to a Tag, using opts for the parts of the
+// tag (name, list-vs-array, time representation) that can't be inferred from
+// rv's type alone.
+func marshalValue(rv reflect.Value, opts fieldOptions) (Tag, error) {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return Tag{}, JsonParseError{"Marshal: nil pointer field " + opts.name, nil}
+		}
+		return marshalValue(rv.Elem(), opts)
+	}
+
+	if rv.Type() == timeType {
+		return marshalTime(rv.Interface().(time.Time), opts), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		children := make([]Tag, 0, rv.NumField())
+		t := rv.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+			fieldOpts := parseFieldTag(field.Tag, field.Name)
+			if fieldOpts.skip {
+				continue
+			}
+			fv := rv.Field(i)
+			if fieldOpts.omitempty && fv.IsZero() {
+				continue
+			}
+			child, err := marshalValue(fv, fieldOpts)
+			if err != nil {
+				return Tag{}, err
+			}
+			children = append(children, child)
+		}
+		return Tag{Name: opts.name, TagType: 10, Compound: children}, nil
+	case reflect.Bool:
+		var b int8
+		if rv.Bool() {
+			b = 1
+		}
+		return Tag{Name: opts.name, TagType: 1, Value: b}, nil
+	case reflect.Int8:
+		return Tag{Name: opts.name, TagType: 1, Value: int8(rv.Int())}, nil
+	case reflect.Int16:
+		return Tag{Name: opts.name, TagType: 2, Value: int16(rv.Int())}, nil
+	case reflect.Int, reflect.Int32:
+		return Tag{Name: opts.name, TagType: 3, Value: int32(rv.Int())}, nil
+	case reflect.Int64:
+		return Tag{Name: opts.name, TagType: 4, Value: rv.Int()}, nil
+	case reflect.Uint8:
+		return Tag{Name: opts.name, TagType: 1, Value: int8(rv.Uint())}, nil
+	case reflect.Uint16:
+		return Tag{Name: opts.name, TagType: 2, Value: int16(rv.Uint())}, nil
+	case reflect.Uint, reflect.Uint32:
+		return Tag{Name: opts.name, TagType: 3, Value: int32(rv.Uint())}, nil
+	case reflect.Uint64:
+		return Tag{Name: opts.name, TagType: 4, Value: int64(rv.Uint())}, nil
+	case reflect.Float32:
+		return Tag{Name: opts.name, TagType: 5, Value: float32(rv.Float())}, nil
+	case reflect.Float64:
+		return Tag{Name: opts.name, TagType: 6, Value: rv.Float()}, nil
+	case reflect.String:
+		return Tag{Name: opts.name, TagType: 8, Value: rv.String()}, nil
+	case reflect.Slice, reflect.Array:
+		return marshalSliceOrArray(rv, opts)
+	default:
+		return Tag{}, JsonParseError{fmt.Sprintf("Marshal: unsupported kind %s for field %s", rv.Kind(), opts.name), nil}
+	}
+}
+
+// marshalSliceOrArray auto-detects TAG_Byte_Array/TAG_Int_Array/TAG_Long_Array
+// from the element kind, unless opts.asList asks for a plain TAG_List instead.
+func marshalSliceOrArray(rv reflect.Value, opts fieldOptions) (Tag, error) {
+	elemKind := rv.Type().Elem().Kind()
+	if !opts.asList {
+		switch elemKind {
+		case reflect.Int8, reflect.Uint8:
+			v := make([]int8, rv.Len())
+			for i := range v {
+				v[i] = int8(reflect.ValueOf(rv.Index(i).Interface()).Convert(reflect.TypeOf(int8(0))).Int())
+			}
+			return Tag{Name: opts.name, TagType: 7, Value: v}, nil
+		case reflect.Int32, reflect.Uint32, reflect.Int, reflect.Uint:
+			v := make([]int32, rv.Len())
+			for i := range v {
+				v[i] = int32(rv.Index(i).Convert(reflect.TypeOf(int32(0))).Int())
+			}
+			return Tag{Name: opts.name, TagType: 11, Value: v}, nil
+		case reflect.Int64, reflect.Uint64:
+			v := make([]int64, rv.Len())
+			for i := range v {
+				v[i] = rv.Index(i).Convert(reflect.TypeOf(int64(0))).Int()
+			}
+			return Tag{Name: opts.name, TagType: 12, Value: v}, nil
+		}
+	}
+
+	list := make([]Tag, rv.Len())
+	var tagListType byte
+	for i := 0; i < rv.Len(); i++ {
+		child, err := marshalValue(rv.Index(i), fieldOptions{name: "", timeMode: opts.timeMode})
+		if err != nil {
+			return Tag{}, err
+		}
+		list[i] = child
+		tagListType = child.TagType
+	}
+	return Tag{Name: opts.name, TagType: 9, TagListType: tagListType, List: list}, nil
+}
+
+func marshalTime(v time.Time, opts fieldOptions) Tag {
+	switch opts.timeMode {
+	case "unixmilli":
+		return Tag{Name: opts.name, TagType: 4, Value: v.UnixNano() / int64(time.Millisecond)}
+	case "rfc3339":
+		return Tag{Name: opts.name, TagType: 8, Value: v.Format(time.RFC3339)}
+	default: // "unix"
+		return Tag{Name: opts.name, TagType: 4, Value: v.Unix()}
+	}
+}
+
+// Unmarshal parses NBT-encoded data laid out per the given Encoding and stores
+// the result into v, which must be a non-nil pointer to a struct, using the
+// same `nbt` struct tags Marshal reads.
+func Unmarshal(data []byte, v interface{}, encoding Encoding) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return JsonParseError{"Unmarshal: v must be a non-nil pointer", nil}
+	}
+	var tag Tag
+	if err := NewDecoder(bytes.NewReader(data), encoding).Decode(&tag); err != nil {
+		return JsonParseError{"Error decoding NBT", err}
+	}
+	return unmarshalValue(tag, rv.Elem())
+}
+
+// unmarshalValue populates rv, a settable reflect.Value, from tag.
+func unmarshalValue(tag Tag, rv reflect.Value) error {
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return unmarshalValue(tag, rv.Elem())
+	}
+
+	if rv.Type() == timeType {
+		return unmarshalTime(tag, rv)
+	}
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		if tag.TagType != 10 {
+			return JsonParseError{"Unmarshal: expected TAG_Compound for field " + tag.Name, nil}
+		}
+		t := rv.Type()
+		byName := make(map[string]int, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			opts := parseFieldTag(field.Tag, field.Name)
+			if opts.skip {
+				continue
+			}
+			byName[opts.name] = i
+		}
+		for _, child := range tag.Compound {
+			i, ok := byName[child.Name]
+			if !ok {
+				continue // unknown fields are ignored, same as encoding/json
+			}
+			if err := unmarshalValue(child, rv.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Bool:
+		rv.SetBool(toInt64(tag.Value) != 0)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		rv.SetInt(toInt64(tag.Value))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		rv.SetUint(uint64(toInt64(tag.Value)))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		rv.SetFloat(toFloat64(tag.Value))
+		return nil
+	case reflect.String:
+		s, _ := tag.Value.(string)
+		rv.SetString(s)
+		return nil
+	case reflect.Slice, reflect.Array:
+		return unmarshalSliceOrArray(tag, rv)
+	default:
+		return JsonParseError{fmt.Sprintf("Unmarshal: unsupported kind %s for field %s", rv.Kind(), tag.Name), nil}
+	}
+}
+
+func unmarshalSliceOrArray(tag Tag, rv reflect.Value) error {
+	elemType := rv.Type().Elem()
+	unsigned := isUnsignedKind(elemType.Kind())
+	switch v := tag.Value.(type) {
+	case []int8:
+		out := reflect.MakeSlice(reflect.SliceOf(elemType), len(v), len(v))
+		for i, b := range v {
+			setIntOrUint(out.Index(i), int64(b), unsigned)
+		}
+		rv.Set(out)
+		return nil
+	case []int32:
+		out := reflect.MakeSlice(reflect.SliceOf(elemType), len(v), len(v))
+		for i, n := range v {
+			setIntOrUint(out.Index(i), int64(n), unsigned)
+		}
+		rv.Set(out)
+		return nil
+	case []int64:
+		out := reflect.MakeSlice(reflect.SliceOf(elemType), len(v), len(v))
+		for i, n := range v {
+			setIntOrUint(out.Index(i), n, unsigned)
+		}
+		rv.Set(out)
+		return nil
+	}
+	if tag.TagType != 9 {
+		return JsonParseError{"Unmarshal: expected TAG_List for field " + tag.Name, nil}
+	}
+	out := reflect.MakeSlice(reflect.SliceOf(elemType), len(tag.List), len(tag.List))
+	for i, child := range tag.List {
+		if err := unmarshalValue(child, out.Index(i)); err != nil {
+			return err
+		}
+	}
+	rv.Set(out)
+	return nil
+}
+
+func unmarshalTime(tag Tag, rv reflect.Value) error {
+	switch v := tag.Value.(type) {
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return JsonParseError{"Unmarshal: invalid RFC3339 time " + v, err}
+		}
+		rv.Set(reflect.ValueOf(t))
+		return nil
+	default:
+		n := toInt64(tag.Value)
+		if tag.TagType == 4 && n > 1e12 { // heuristic: treat suspiciously large values as millis
+			rv.Set(reflect.ValueOf(time.Unix(0, n*int64(time.Millisecond))))
+			return nil
+		}
+		rv.Set(reflect.ValueOf(time.Unix(n, 0)))
+		return nil
+	}
+}
+
+// isUnsignedKind reports whether k is one of the unsigned integer kinds, so
+// unmarshalSliceOrArray knows whether a slice element (e.g. []byte, which is
+// []uint8) needs SetUint instead of SetInt.
+func isUnsignedKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// setIntOrUint mirrors the scalar handling in unmarshalValue: it calls
+// SetUint for unsigned element kinds and SetInt otherwise.
+func setIntOrUint(elem reflect.Value, n int64, unsigned bool) {
+	if unsigned {
+		elem.SetUint(uint64(n))
+		return
+	}
+	elem.SetInt(n)
+}
+
+// toInt64 widens any of the integer types readPayload produces to int64.
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	default:
+		return 0
+	}
+}
+
+// toFloat64 widens either float type readPayload produces to float64.
+func toFloat64(v interface{}) float64 {
+	switch n := v.(type) {
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	default:
+		return 0
+	}
+}
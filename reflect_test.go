@@ -0,0 +1,26 @@
+package nbt2json
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalUnmarshalByteSlice(t *testing.T) {
+	type Item struct {
+		Data []byte
+	}
+	in := Item{Data: []byte{0, 1, 2, 255, 254}}
+
+	b, err := Marshal(in, EncodingJavaBig)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var out Item
+	if err := Unmarshal(b, &out, EncodingJavaBig); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if !bytes.Equal(out.Data, in.Data) {
+		t.Fatalf("round-trip mismatch: got %v, want %v", out.Data, in.Data)
+	}
+}
@@ -0,0 +1,240 @@
+// Package region reads and writes Minecraft Anvil/Region (.mca/.mcr) files:
+// the on-disk format that packs up to 32x32 chunks of a world into a single
+// file, each chunk individually compressed. It hands callers the raw,
+// decompressed NBT byte array for a chunk; converting that to/from JSON is
+// left to the parent nbt2json package.
+package region
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	sectorSize = 4096
+	headerSize = 2 * sectorSize // 1024 4-byte offsets, then 1024 4-byte mtimes
+	entryCount = 1024
+)
+
+// CompressionScheme identifies how one chunk's payload is compressed within a
+// region file, per the 1-byte scheme tag stored before the payload.
+type CompressionScheme byte
+
+// The four compression schemes Anvil region files use. CompressionLZ4 is
+// recognized but not yet supported for reading or writing.
+const (
+	CompressionGZip CompressionScheme = 1
+	CompressionZlib CompressionScheme = 2
+	CompressionNone CompressionScheme = 3
+	CompressionLZ4  CompressionScheme = 4
+)
+
+type chunkLocation struct {
+	sectorOffset uint32
+	sectorCount  uint8
+	mtime        uint32
+}
+
+// Region is an open Anvil/Region file. Chunk coordinates passed to its
+// methods are relative to the region, 0-31 on each axis.
+type Region struct {
+	path     string
+	location [entryCount]chunkLocation
+	chunks   map[int][]byte
+	scheme   map[int]CompressionScheme
+	dirty    map[int]bool
+}
+
+func chunkIndex(x, z int) (int, error) {
+	if x < 0 || x > 31 || z < 0 || z > 31 {
+		return 0, fmt.Errorf("region: chunk coordinates %d,%d out of range 0-31", x, z)
+	}
+	return x + z*32, nil
+}
+
+// OpenRegion reads and decompresses every present chunk in the region file at
+// path.
+func OpenRegion(path string) (*Region, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("region: error opening %s: %w", path, err)
+	}
+	if len(data) < headerSize {
+		return nil, fmt.Errorf("region: %s is smaller than the %d-byte header", path, headerSize)
+	}
+
+	r := &Region{
+		path:   path,
+		chunks: make(map[int][]byte),
+		scheme: make(map[int]CompressionScheme),
+		dirty:  make(map[int]bool),
+	}
+	for i := 0; i < entryCount; i++ {
+		loc := binary.BigEndian.Uint32(data[i*4 : i*4+4])
+		r.location[i] = chunkLocation{
+			sectorOffset: loc >> 8,
+			sectorCount:  uint8(loc & 0xff),
+			mtime:        binary.BigEndian.Uint32(data[sectorSize+i*4 : sectorSize+i*4+4]),
+		}
+	}
+
+	for i, loc := range r.location {
+		if loc.sectorOffset == 0 && loc.sectorCount == 0 {
+			continue // chunk has not been generated
+		}
+		start := int(loc.sectorOffset) * sectorSize
+		if start+5 > len(data) {
+			return nil, fmt.Errorf("region: chunk entry %d points past end of file", i)
+		}
+		length := binary.BigEndian.Uint32(data[start : start+4])
+		scheme := CompressionScheme(data[start+4])
+		payloadEnd := start + 4 + int(length)
+		if length == 0 || payloadEnd > len(data) {
+			return nil, fmt.Errorf("region: chunk entry %d payload runs past end of file", i)
+		}
+		nbt, err := decompress(scheme, data[start+5:payloadEnd])
+		if err != nil {
+			return nil, fmt.Errorf("region: error decompressing chunk %d: %w", i, err)
+		}
+		r.chunks[i] = nbt
+		r.scheme[i] = scheme
+	}
+	return r, nil
+}
+
+// Chunk returns the decompressed NBT byte array for the chunk at (x, z). It
+// returns a nil byte array and a nil error if that chunk has never been
+// generated.
+func (r *Region) Chunk(x, z int) ([]byte, error) {
+	i, err := chunkIndex(x, z)
+	if err != nil {
+		return nil, err
+	}
+	return r.chunks[i], nil
+}
+
+// SetChunk stores nbt as the payload for the chunk at (x, z), to be written
+// out the next time Flush is called. New chunks are compressed with zlib,
+// matching the scheme current Minecraft versions write.
+func (r *Region) SetChunk(x, z int, nbt []byte) error {
+	i, err := chunkIndex(x, z)
+	if err != nil {
+		return err
+	}
+	r.chunks[i] = nbt
+	if _, ok := r.scheme[i]; !ok {
+		r.scheme[i] = CompressionZlib
+	}
+	r.dirty[i] = true
+	return nil
+}
+
+// Flush repacks the whole region file from its in-memory chunk data and
+// writes it back to disk, honoring the format's 4 KiB sector alignment.
+func (r *Region) Flush() error {
+	header := make([]byte, headerSize)
+	var body bytes.Buffer
+	sector := headerSize / sectorSize
+	now := uint32(time.Now().Unix())
+
+	for i := 0; i < entryCount; i++ {
+		nbt, ok := r.chunks[i]
+		if !ok {
+			continue
+		}
+		compressed, err := compress(r.scheme[i], nbt)
+		if err != nil {
+			return fmt.Errorf("region: error compressing chunk %d: %w", i, err)
+		}
+		payload := make([]byte, 5+len(compressed))
+		binary.BigEndian.PutUint32(payload, uint32(len(compressed)+1))
+		payload[4] = byte(r.scheme[i])
+		copy(payload[5:], compressed)
+
+		sectorCount := (len(payload) + sectorSize - 1) / sectorSize
+		if sectorCount > 255 {
+			return fmt.Errorf("region: chunk %d needs %d sectors, more than the format's 255-sector limit", i, sectorCount)
+		}
+		padded := make([]byte, sectorCount*sectorSize)
+		copy(padded, payload)
+		body.Write(padded)
+
+		binary.BigEndian.PutUint32(header[i*4:i*4+4], uint32(sector)<<8|uint32(sectorCount))
+		mtime := r.location[i].mtime
+		if r.dirty[i] {
+			mtime = now
+		}
+		binary.BigEndian.PutUint32(header[sectorSize+i*4:sectorSize+i*4+4], mtime)
+		r.location[i] = chunkLocation{sectorOffset: uint32(sector), sectorCount: uint8(sectorCount), mtime: mtime}
+
+		sector += sectorCount
+	}
+
+	if err := os.WriteFile(r.path, append(header, body.Bytes()...), 0644); err != nil {
+		return fmt.Errorf("region: error writing %s: %w", r.path, err)
+	}
+	for i := range r.dirty {
+		delete(r.dirty, i)
+	}
+	return nil
+}
+
+func decompress(scheme CompressionScheme, b []byte) ([]byte, error) {
+	switch scheme {
+	case CompressionGZip:
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	case CompressionZlib:
+		zr, err := zlib.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	case CompressionNone:
+		out := make([]byte, len(b))
+		copy(out, b)
+		return out, nil
+	case CompressionLZ4:
+		return nil, fmt.Errorf("region: lz4 chunk compression is not yet supported")
+	default:
+		return nil, fmt.Errorf("region: unrecognized compression scheme %d", scheme)
+	}
+}
+
+func compress(scheme CompressionScheme, b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	switch scheme {
+	case CompressionGZip:
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(b); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	case CompressionZlib:
+		zw := zlib.NewWriter(&buf)
+		if _, err := zw.Write(b); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	case CompressionNone:
+		buf.Write(b)
+	default:
+		return nil, fmt.Errorf("region: unsupported compression scheme %d for writing", scheme)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,48 @@
+package region
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetChunkFlushOpenRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "r.0.0.mca")
+	if err := os.WriteFile(path, make([]byte, headerSize), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	r, err := OpenRegion(path)
+	if err != nil {
+		t.Fatalf("OpenRegion: %v", err)
+	}
+
+	nbt := []byte("fake uncompressed NBT payload")
+	if err := r.SetChunk(5, 7, nbt); err != nil {
+		t.Fatalf("SetChunk: %v", err)
+	}
+	if err := r.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	r2, err := OpenRegion(path)
+	if err != nil {
+		t.Fatalf("OpenRegion (reopen): %v", err)
+	}
+	got, err := r2.Chunk(5, 7)
+	if err != nil {
+		t.Fatalf("Chunk: %v", err)
+	}
+	if !bytes.Equal(got, nbt) {
+		t.Fatalf("round-trip mismatch: got %q, want %q", got, nbt)
+	}
+
+	empty, err := r2.Chunk(0, 0)
+	if err != nil {
+		t.Fatalf("Chunk(0,0): %v", err)
+	}
+	if empty != nil {
+		t.Fatalf("never-generated chunk: got %q, want nil", empty)
+	}
+}
@@ -0,0 +1,538 @@
+package nbt2json
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Snbt2Nbt converts SNBT ("stringified NBT", the compact textual form used by
+// Minecraft's /data command and by datapack files, e.g.
+// `{Health:20.0f,Inventory:[{id:"minecraft:stone",Count:64b}]}`) to an
+// uncompressed NBT byte array.
+func Snbt2Nbt(b []byte, order binary.ByteOrder) ([]byte, error) {
+	p := &snbtParser{s: string(b)}
+	tag, err := p.parseValue("")
+	if err != nil {
+		return nil, JsonParseError{"Error parsing SNBT input", err}
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, JsonParseError{"Error parsing SNBT input: unexpected trailing data", nil}
+	}
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, FromByteOrder(order)).Encode(tag); err != nil {
+		return nil, JsonParseError{"Error encoding NBT from SNBT", err}
+	}
+	return buf.Bytes(), nil
+}
+
+// Nbt2Snbt converts an uncompressed NBT byte array to its canonical SNBT text
+// representation: type suffixes (b/s/L/f/d) and typed array prefixes ([B;
+// [I; [L;) are always emitted, regardless of whether the original SNBT this
+// was parsed from used them.
+func Nbt2Snbt(b []byte, order binary.ByteOrder) ([]byte, error) {
+	var tag Tag
+	if err := NewDecoder(bytes.NewReader(b), FromByteOrder(order)).Decode(&tag); err != nil {
+		return nil, JsonParseError{"Error decoding NBT", err}
+	}
+	out := new(bytes.Buffer)
+	writeSnbtValue(out, tag)
+	return out.Bytes(), nil
+}
+
+// snbtParser is a small hand-written scanner over SNBT text, in the spirit of
+// encoding/json's internal scanner: it walks the byte string once, left to
+// right, with no backtracking beyond a single lookahead byte.
+type snbtParser struct {
+	s   string
+	pos int
+}
+
+func (p *snbtParser) skipSpace() {
+	for p.pos < len(p.s) && isSnbtSpace(p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *snbtParser) peek() (byte, bool) {
+	if p.pos >= len(p.s) {
+		return 0, false
+	}
+	return p.s[p.pos], true
+}
+
+func (p *snbtParser) parseValue(name string) (Tag, error) {
+	p.skipSpace()
+	c, ok := p.peek()
+	if !ok {
+		return Tag{}, fmt.Errorf("unexpected end of input")
+	}
+	switch c {
+	case '{':
+		return p.parseCompound(name)
+	case '[':
+		return p.parseListOrArray(name)
+	case '"', '\'':
+		s, err := p.parseQuotedString()
+		if err != nil {
+			return Tag{}, err
+		}
+		return Tag{Name: name, TagType: 8, Value: s}, nil
+	default:
+		return p.parseBare(name)
+	}
+}
+
+func (p *snbtParser) parseCompound(name string) (Tag, error) {
+	p.pos++ // consume '{'
+	var children []Tag
+	p.skipSpace()
+	if c, ok := p.peek(); ok && c == '}' {
+		p.pos++
+		return Tag{Name: name, TagType: 10, Compound: children}, nil
+	}
+	for {
+		p.skipSpace()
+		key, err := p.parseKey()
+		if err != nil {
+			return Tag{}, err
+		}
+		p.skipSpace()
+		if c, ok := p.peek(); !ok || c != ':' {
+			return Tag{}, fmt.Errorf("expected ':' after key %q", key)
+		}
+		p.pos++
+		child, err := p.parseValue(key)
+		if err != nil {
+			return Tag{}, err
+		}
+		children = append(children, child)
+		p.skipSpace()
+		c, ok := p.peek()
+		if !ok {
+			return Tag{}, fmt.Errorf("unterminated compound")
+		}
+		if c == ',' {
+			p.pos++
+			continue
+		}
+		if c == '}' {
+			p.pos++
+			break
+		}
+		return Tag{}, fmt.Errorf("expected ',' or '}' in compound, got %q", c)
+	}
+	return Tag{Name: name, TagType: 10, Compound: children}, nil
+}
+
+func (p *snbtParser) parseKey() (string, error) {
+	c, ok := p.peek()
+	if !ok {
+		return "", fmt.Errorf("unexpected end of input reading key")
+	}
+	if c == '"' || c == '\'' {
+		return p.parseQuotedString()
+	}
+	start := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != ':' && !isSnbtSpace(p.s[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("empty key")
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *snbtParser) parseListOrArray(name string) (Tag, error) {
+	p.pos++ // consume '['
+	if p.pos+1 < len(p.s) && p.s[p.pos+1] == ';' {
+		switch p.s[p.pos] {
+		case 'B', 'b':
+			p.pos += 2
+			return p.parseTypedArray(name, 7)
+		case 'I', 'i':
+			p.pos += 2
+			return p.parseTypedArray(name, 11)
+		case 'L', 'l':
+			p.pos += 2
+			return p.parseTypedArray(name, 12)
+		}
+	}
+
+	var list []Tag
+	var tagListType byte
+	p.skipSpace()
+	if c, ok := p.peek(); ok && c == ']' {
+		p.pos++
+		return Tag{Name: name, TagType: 9, List: list}, nil
+	}
+	for {
+		child, err := p.parseValue("")
+		if err != nil {
+			return Tag{}, err
+		}
+		if tagListType == 0 {
+			tagListType = child.TagType
+		}
+		list = append(list, child)
+		p.skipSpace()
+		c, ok := p.peek()
+		if !ok {
+			return Tag{}, fmt.Errorf("unterminated list")
+		}
+		if c == ',' {
+			p.pos++
+			p.skipSpace()
+			continue
+		}
+		if c == ']' {
+			p.pos++
+			break
+		}
+		return Tag{}, fmt.Errorf("expected ',' or ']' in list, got %q", c)
+	}
+	return Tag{Name: name, TagType: 9, TagListType: tagListType, List: list}, nil
+}
+
+// parseTypedArray parses the elements of a [B;...], [I;...], or [L;...]
+// typed array; the ';' has already been consumed.
+func (p *snbtParser) parseTypedArray(name string, tagType byte) (Tag, error) {
+	elems, err := p.parseIntArrayElements()
+	if err != nil {
+		return Tag{}, err
+	}
+	switch tagType {
+	case 7:
+		v := make([]int8, len(elems))
+		for i, n := range elems {
+			v[i] = int8(n)
+		}
+		return Tag{Name: name, TagType: 7, Value: v}, nil
+	case 11:
+		v := make([]int32, len(elems))
+		for i, n := range elems {
+			v[i] = int32(n)
+		}
+		return Tag{Name: name, TagType: 11, Value: v}, nil
+	default: // 12
+		return Tag{Name: name, TagType: 12, Value: elems}, nil
+	}
+}
+
+func (p *snbtParser) parseIntArrayElements() ([]int64, error) {
+	var out []int64
+	p.skipSpace()
+	if c, ok := p.peek(); ok && c == ']' {
+		p.pos++
+		return out, nil
+	}
+	for {
+		p.skipSpace()
+		tok, err := p.scanNumberToken()
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseInt(strings.TrimRight(tok, "bBsSlL"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array element %q: %s", tok, err)
+		}
+		out = append(out, n)
+		p.skipSpace()
+		c, ok := p.peek()
+		if !ok {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		if c == ',' {
+			p.pos++
+			continue
+		}
+		if c == ']' {
+			p.pos++
+			break
+		}
+		return nil, fmt.Errorf("expected ',' or ']' in array, got %q", c)
+	}
+	return out, nil
+}
+
+func (p *snbtParser) scanNumberToken() (string, error) {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c == ',' || c == ']' || c == '}' || isSnbtSpace(c) {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("expected number")
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *snbtParser) parseQuotedString() (string, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.s) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := p.s[p.pos]
+		if c == '\\' && p.pos+1 < len(p.s) {
+			sb.WriteByte(p.s[p.pos+1])
+			p.pos += 2
+			continue
+		}
+		if c == quote {
+			p.pos++
+			return sb.String(), nil
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+}
+
+// parseBare parses an unquoted token: true/false, a number with an optional
+// type suffix, or a bare string such as an unquoted resource location.
+func (p *snbtParser) parseBare(name string) (Tag, error) {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if c == ',' || c == '}' || c == ']' || isSnbtSpace(c) {
+			break
+		}
+		p.pos++
+	}
+	tok := p.s[start:p.pos]
+	if tok == "" {
+		return Tag{}, fmt.Errorf("expected value")
+	}
+	switch tok {
+	case "true":
+		return Tag{Name: name, TagType: 1, Value: int8(1)}, nil
+	case "false":
+		return Tag{Name: name, TagType: 1, Value: int8(0)}, nil
+	}
+	if tag, ok := parseSnbtNumber(name, tok); ok {
+		return tag, nil
+	}
+	return Tag{Name: name, TagType: 8, Value: tok}, nil
+}
+
+// parseSnbtNumber recognizes a number token, preserving its type suffix
+// (b/s/L/f/d) where present; an unsuffixed integer that fits in an int32 is
+// TAG_Int, and any other unsuffixed number (a larger integer, or a value with a
+// decimal point or exponent) is TAG_Double.
+func parseSnbtNumber(name, tok string) (Tag, bool) {
+	body := tok
+	var suffix byte
+	last := tok[len(tok)-1]
+	switch last {
+	case 'b', 'B', 's', 'S', 'l', 'L', 'f', 'F', 'd', 'D':
+		candidate := tok[:len(tok)-1]
+		if isNumericBody(candidate) {
+			suffix = last
+			body = candidate
+		}
+	}
+	switch suffix {
+	case 'b', 'B':
+		n, err := strconv.ParseInt(body, 10, 8)
+		if err != nil {
+			return Tag{}, false
+		}
+		return Tag{Name: name, TagType: 1, Value: int8(n)}, true
+	case 's', 'S':
+		n, err := strconv.ParseInt(body, 10, 16)
+		if err != nil {
+			return Tag{}, false
+		}
+		return Tag{Name: name, TagType: 2, Value: int16(n)}, true
+	case 'l', 'L':
+		n, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return Tag{}, false
+		}
+		return Tag{Name: name, TagType: 4, Value: n}, true
+	case 'f', 'F':
+		f, err := strconv.ParseFloat(body, 32)
+		if err != nil {
+			return Tag{}, false
+		}
+		return Tag{Name: name, TagType: 5, Value: float32(f)}, true
+	case 'd', 'D':
+		f, err := strconv.ParseFloat(body, 64)
+		if err != nil {
+			return Tag{}, false
+		}
+		return Tag{Name: name, TagType: 6, Value: f}, true
+	default:
+		if !isNumericBody(body) {
+			return Tag{}, false
+		}
+		if n, err := strconv.ParseInt(body, 10, 32); err == nil {
+			return Tag{Name: name, TagType: 3, Value: int32(n)}, true
+		}
+		if f, err := strconv.ParseFloat(body, 64); err == nil {
+			return Tag{Name: name, TagType: 6, Value: f}, true
+		}
+		return Tag{}, false
+	}
+}
+
+// isNumericBody reports whether s looks like the digits of a number (with an
+// optional leading sign, decimal point, or exponent) rather than a bare
+// string that merely happens to end in a letter shared with a type suffix.
+func isNumericBody(s string) bool {
+	if s == "" {
+		return false
+	}
+	i := 0
+	if s[0] == '+' || s[0] == '-' {
+		i++
+	}
+	if i >= len(s) {
+		return false
+	}
+	seenDigit := false
+	for ; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			seenDigit = true
+		case c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-':
+			// allowed as part of a float/exponent; validity beyond this is left
+			// to strconv.ParseInt/ParseFloat
+		default:
+			return false
+		}
+	}
+	return seenDigit
+}
+
+func isSnbtSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// writeSnbtValue appends tag's canonical SNBT text to w.
+func writeSnbtValue(w *bytes.Buffer, tag Tag) {
+	switch tag.TagType {
+	case 1:
+		fmt.Fprintf(w, "%db", tag.Value.(int8))
+	case 2:
+		fmt.Fprintf(w, "%ds", tag.Value.(int16))
+	case 3:
+		fmt.Fprintf(w, "%d", tag.Value.(int32))
+	case 4:
+		fmt.Fprintf(w, "%dL", tag.Value.(int64))
+	case 5:
+		w.WriteString(formatSnbtFloat(float64(tag.Value.(float32)), 32))
+		w.WriteByte('f')
+	case 6:
+		w.WriteString(formatSnbtFloat(tag.Value.(float64), 64))
+		w.WriteByte('d')
+	case 7:
+		w.WriteString("[B;")
+		for i, b := range tag.Value.([]int8) {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%db", b)
+		}
+		w.WriteByte(']')
+	case 8:
+		writeSnbtString(w, tag.Value.(string))
+	case 9:
+		w.WriteByte('[')
+		for i, child := range tag.List {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeSnbtValue(w, child)
+		}
+		w.WriteByte(']')
+	case 10:
+		w.WriteByte('{')
+		for i, child := range tag.Compound {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeSnbtKey(w, child.Name)
+			w.WriteByte(':')
+			writeSnbtValue(w, child)
+		}
+		w.WriteByte('}')
+	case 11:
+		w.WriteString("[I;")
+		for i, n := range tag.Value.([]int32) {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%d", n)
+		}
+		w.WriteByte(']')
+	case 12:
+		w.WriteString("[L;")
+		for i, n := range tag.Value.([]int64) {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, "%dL", n)
+		}
+		w.WriteByte(']')
+	}
+}
+
+// formatSnbtFloat formats f so it always contains a decimal point or
+// exponent, so a whole-number float isn't mistaken for a TAG_Int once its
+// type suffix is stripped back off by a reader.
+func formatSnbtFloat(f float64, bitSize int) string {
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+func writeSnbtString(w *bytes.Buffer, s string) {
+	w.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' || c == '\\' {
+			w.WriteByte('\\')
+		}
+		w.WriteByte(c)
+	}
+	w.WriteByte('"')
+}
+
+func writeSnbtKey(w *bytes.Buffer, key string) {
+	if isBareKey(key) {
+		w.WriteString(key)
+		return
+	}
+	writeSnbtString(w, key)
+}
+
+// isBareKey reports whether key can be written without quotes: Minecraft
+// allows bare compound keys made up of letters, digits, underscore, '+', '-',
+// and '.'.
+func isBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '_' || c == '+' || c == '-' || c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package nbt2json
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestSnbt2NbtNbt2SnbtRoundTrip(t *testing.T) {
+	in := `{Health:20.0f,Name:"Steve",Inventory:[{id:"minecraft:stone",Count:64b}],Pos:[I;1,2,3],Seeds:[L;1,2,3]}`
+
+	nbt, err := Snbt2Nbt([]byte(in), binary.BigEndian)
+	if err != nil {
+		t.Fatalf("Snbt2Nbt: %v", err)
+	}
+
+	out, err := Nbt2Snbt(nbt, binary.BigEndian)
+	if err != nil {
+		t.Fatalf("Nbt2Snbt: %v", err)
+	}
+
+	want := `{Health:20.0f,Name:"Steve",Inventory:[{id:"minecraft:stone",Count:64b}],Pos:[I;1,2,3],Seeds:[L;1L,2L,3L]}`
+	if string(out) != want {
+		t.Fatalf("round-trip mismatch:\n got:  %s\n want: %s", out, want)
+	}
+
+	nbt2, err := Snbt2Nbt(out, binary.BigEndian)
+	if err != nil {
+		t.Fatalf("Snbt2Nbt (canonical form): %v", err)
+	}
+	out2, err := Nbt2Snbt(nbt2, binary.BigEndian)
+	if err != nil {
+		t.Fatalf("Nbt2Snbt (second pass): %v", err)
+	}
+	if string(out2) != want {
+		t.Fatalf("canonical form did not round-trip:\n got:  %s\n want: %s", out2, want)
+	}
+}
+
+func TestSnbt2NbtByteArrayElementSuffix(t *testing.T) {
+	nbt, err := Snbt2Nbt([]byte(`{Flags:[B;1b,2b,127b]}`), binary.BigEndian)
+	if err != nil {
+		t.Fatalf("Snbt2Nbt: %v", err)
+	}
+	out, err := Nbt2Snbt(nbt, binary.BigEndian)
+	if err != nil {
+		t.Fatalf("Nbt2Snbt: %v", err)
+	}
+	want := `{Flags:[B;1b,2b,127b]}`
+	if string(out) != want {
+		t.Fatalf("byte array round-trip mismatch: got %s, want %s", out, want)
+	}
+}
@@ -0,0 +1,509 @@
+package nbt2json
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// TokenType identifies the kind of event returned by Decoder.Token, mirroring how
+// xml.Decoder distinguishes StartElement, EndElement, and CharData.
+type TokenType int
+
+const (
+	// TagStart marks the start of a TAG_Compound or TAG_List. TagType is always
+	// set, and Name is too except on a list element; TagListType is set when
+	// TagType is TAG_List.
+	TagStart TokenType = iota
+	// TagEnd marks the close of a TAG_Compound (the TAG_End byte) or the close of
+	// a TAG_List once its element count has been exhausted.
+	TagEnd
+	// Value carries the payload of a scalar tag (TAG_Byte .. TAG_Double, TAG_String,
+	// the *_Array tags) or, inside a TAG_List, a single element of that list.
+	Value
+)
+
+// Token is one event in an NBT byte stream, produced by Decoder.Token and consumed
+// by Encoder.EncodeToken. Name is unset on list elements, which have no name;
+// their TagType is filled in from the enclosing TagStart's TagListType.
+type Token struct {
+	Type        TokenType
+	Name        string
+	TagType     byte
+	TagListType byte // set on TagStart when TagType == 9 (TAG_List)
+	Length      int  // set on TagStart when TagType == 9: number of elements in the list
+	Value       interface{}
+}
+
+// frame tracks one level of nesting so Token/Encode can tell a bare Value token
+// apart from a list element, and so a list's declared Length can be honored.
+type frame struct {
+	isList      bool
+	tagListType byte
+	remaining   int
+}
+
+// Decoder reads a stream of Tokens from an NBT byte stream, holding only the
+// current nesting path and the current token in memory, so a large tree can be
+// consumed tag by tag. A *_Array tag, such as a chunk's Blocks or Data array, is
+// read whole into a single Value token; its elements are not streamed one by one.
+type Decoder struct {
+	r     io.Reader
+	enc   Encoding
+	stack []frame
+}
+
+// NewDecoder returns a Decoder that reads NBT laid out per the given Encoding,
+// e.g. EncodingJavaBig for Java Edition or EncodingBedrockVarint for a Bedrock
+// disk save.
+func NewDecoder(r io.Reader, encoding Encoding) *Decoder {
+	return &Decoder{r: r, enc: encoding}
+}
+
+func (d *Decoder) push(f frame) { d.stack = append(d.stack, f) }
+func (d *Decoder) pop()         { d.stack = d.stack[:len(d.stack)-1] }
+func (d *Decoder) top() *frame {
+	if len(d.stack) == 0 {
+		return nil
+	}
+	return &d.stack[len(d.stack)-1]
+}
+
+// Token returns the next TagStart, TagEnd, or Value event in the stream. Callers
+// drive a loop calling Token until it returns io.EOF, pushing onto their own stack
+// (or none at all, for callers that only care about leaf values) as TagStart and
+// TagEnd arrive.
+func (d *Decoder) Token() (Token, error) {
+	if f := d.top(); f != nil && f.isList {
+		if f.remaining == 0 {
+			d.pop()
+			return Token{Type: TagEnd}, nil
+		}
+		f.remaining--
+		// List elements carry no type/name header of their own: the list's own
+		// header already declared every element's tagType.
+		switch f.tagListType {
+		case 9: // element is itself a TAG_List
+			var tagListType byte
+			if err := binary.Read(d.r, d.enc.byteOrder, &tagListType); err != nil {
+				return Token{}, JsonParseError{"Error reading nested tag list type", err}
+			}
+			length, err := readContainerLen(d.r, d.enc)
+			if err != nil {
+				return Token{}, JsonParseError{"Error reading nested tag list length", err}
+			}
+			d.push(frame{isList: true, tagListType: tagListType, remaining: length})
+			return Token{Type: TagStart, TagType: 9, TagListType: tagListType, Length: length}, nil
+		case 10: // element is a TAG_Compound
+			d.push(frame{})
+			return Token{Type: TagStart, TagType: 10}, nil
+		default:
+			value, err := readPayload(d.r, d.enc, f.tagListType)
+			if err != nil {
+				return Token{}, JsonParseError{"Error reading list element", err}
+			}
+			return Token{Type: Value, TagType: f.tagListType, Value: value}, nil
+		}
+	}
+
+	var tagType byte
+	err := binary.Read(d.r, d.enc.byteOrder, &tagType)
+	if err != nil {
+		return Token{}, err // io.EOF at a tag boundary is the normal end of stream
+	}
+	if tagType == 0 {
+		d.pop()
+		return Token{Type: TagEnd}, nil
+	}
+
+	nameLen, err := readStringLen(d.r, d.enc)
+	if err != nil {
+		return Token{}, JsonParseError{"Error reading tag name length", err}
+	}
+	nameBytes := make([]byte, nameLen)
+	if _, err = io.ReadFull(d.r, nameBytes); err != nil {
+		return Token{}, JsonParseError{"Error reading tag name", err}
+	}
+	name := string(nameBytes)
+
+	switch tagType {
+	case 9: // TAG_List
+		var tagListType byte
+		if err = binary.Read(d.r, d.enc.byteOrder, &tagListType); err != nil {
+			return Token{}, JsonParseError{"Error reading tag list type", err}
+		}
+		length, err := readContainerLen(d.r, d.enc)
+		if err != nil {
+			return Token{}, JsonParseError{"Error reading tag list length", err}
+		}
+		d.push(frame{isList: true, tagListType: tagListType, remaining: length})
+		return Token{Type: TagStart, Name: name, TagType: tagType, TagListType: tagListType, Length: length}, nil
+	case 10: // TAG_Compound
+		d.push(frame{})
+		return Token{Type: TagStart, Name: name, TagType: tagType}, nil
+	default:
+		value, err := readPayload(d.r, d.enc, tagType)
+		if err != nil {
+			return Token{}, err
+		}
+		return Token{Type: Value, Name: name, TagType: tagType, Value: value}, nil
+	}
+}
+
+// Decode reads the next complete top-level tag into v, which must be a *Tag. It is
+// a convenience wrapper around Token for callers who want an in-memory tree rather
+// than a raw event stream.
+func (d *Decoder) Decode(v interface{}) error {
+	tag, ok := v.(*Tag)
+	if !ok {
+		return JsonParseError{"Decoder.Decode: v must be a *Tag", nil}
+	}
+	t, err := d.Token()
+	if err != nil {
+		return err
+	}
+	built, err := d.buildTag(t)
+	if err != nil {
+		return err
+	}
+	*tag = built
+	return nil
+}
+
+// buildTag consumes whatever additional tokens are needed to turn the token just
+// read into a fully populated Tag, recursing into children for TAG_List and
+// TAG_Compound.
+func (d *Decoder) buildTag(t Token) (Tag, error) {
+	tag := Tag{Name: t.Name, TagType: t.TagType, TagListType: t.TagListType}
+	switch t.Type {
+	case Value:
+		tag.Value = t.Value
+		return tag, nil
+	case TagStart:
+		if t.TagType == 9 {
+			tag.List = make([]Tag, 0, t.Length)
+			for {
+				child, err := d.Token()
+				if err != nil {
+					return Tag{}, err
+				}
+				if child.Type == TagEnd {
+					return tag, nil
+				}
+				built, err := d.buildTag(child)
+				if err != nil {
+					return Tag{}, err
+				}
+				tag.List = append(tag.List, built)
+			}
+		}
+		for {
+			child, err := d.Token()
+			if err != nil {
+				return Tag{}, err
+			}
+			if child.Type == TagEnd {
+				return tag, nil
+			}
+			built, err := d.buildTag(child)
+			if err != nil {
+				return Tag{}, err
+			}
+			tag.Compound = append(tag.Compound, built)
+		}
+	default:
+		return Tag{}, JsonParseError{"buildTag: unexpected TagEnd as first token", nil}
+	}
+}
+
+// Encoder writes a stream of Tokens as NBT bytes to an io.Writer.
+type Encoder struct {
+	w     io.Writer
+	enc   Encoding
+	stack []frame
+}
+
+// NewEncoder returns an Encoder that writes NBT laid out per the given Encoding,
+// e.g. EncodingJavaBig for Java Edition or EncodingBedrockNetwork for a Bedrock
+// packet.
+func NewEncoder(w io.Writer, encoding Encoding) *Encoder {
+	return &Encoder{w: w, enc: encoding}
+}
+
+func (e *Encoder) push(f frame) { e.stack = append(e.stack, f) }
+func (e *Encoder) pop()         { e.stack = e.stack[:len(e.stack)-1] }
+func (e *Encoder) top() *frame {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	return &e.stack[len(e.stack)-1]
+}
+
+// writeHeader writes a tag's type byte and name, as every non-list-element tag
+// requires.
+func (e *Encoder) writeHeader(tagType byte, name string) error {
+	if err := binary.Write(e.w, e.enc.byteOrder, tagType); err != nil {
+		return JsonParseError{"Error writing tagType", err}
+	}
+	if err := writeStringLen(e.w, e.enc, len(name)); err != nil {
+		return JsonParseError{"Error writing name length", err}
+	}
+	if err := binary.Write(e.w, e.enc.byteOrder, []byte(name)); err != nil {
+		return JsonParseError{"Error writing name", err}
+	}
+	return nil
+}
+
+// EncodeToken writes one Token to the underlying writer. Tokens must be supplied
+// in the same order Decoder.Token would produce them: a TagStart for TAG_List or
+// TAG_Compound must eventually be balanced by a matching TagEnd, and Value tokens
+// emitted while inside a TAG_List are written as bare elements with no tag header.
+// Most callers want the simpler Encode, which writes a whole Tag at once; EncodeToken
+// is for callers driving the write incrementally, e.g. streaming a large list
+// element by element.
+func (e *Encoder) EncodeToken(t Token) error {
+	f := e.top()
+	inList := f != nil && f.isList
+	switch t.Type {
+	case Value:
+		if inList {
+			return writePayloadValue(e.w, e.enc, f.tagListType, t.Value)
+		}
+		if err := e.writeHeader(t.TagType, t.Name); err != nil {
+			return err
+		}
+		return writePayloadValue(e.w, e.enc, t.TagType, t.Value)
+	case TagStart:
+		// Elements of a TAG_List carry no type/name header of their own: the
+		// list's own header already declared every element's tagType.
+		if !inList {
+			if err := e.writeHeader(t.TagType, t.Name); err != nil {
+				return err
+			}
+		}
+		if t.TagType == 9 {
+			if err := binary.Write(e.w, e.enc.byteOrder, t.TagListType); err != nil {
+				return JsonParseError{"Error writing tag list type", err}
+			}
+			if err := writeContainerLen(e.w, e.enc, t.Length); err != nil {
+				return JsonParseError{"Error writing tag list length", err}
+			}
+			e.push(frame{isList: true, tagListType: t.TagListType})
+			return nil
+		}
+		e.push(frame{})
+		return nil
+	case TagEnd:
+		f := e.top()
+		if f == nil {
+			return JsonParseError{"Encode: TagEnd with no open TagStart", nil}
+		}
+		e.pop()
+		if f.isList {
+			return nil
+		}
+		return binary.Write(e.w, e.enc.byteOrder, byte(0))
+	default:
+		return JsonParseError{"EncodeToken: unrecognized token type", nil}
+	}
+}
+
+// Encode writes a complete Tag, including all of its List or Compound children,
+// to the underlying writer.
+func (e *Encoder) Encode(tag Tag) error {
+	switch tag.TagType {
+	case 9: // TAG_List
+		if err := e.EncodeToken(Token{Type: TagStart, Name: tag.Name, TagType: 9, TagListType: tag.TagListType, Length: len(tag.List)}); err != nil {
+			return err
+		}
+		for _, child := range tag.List {
+			// Each child is re-encoded through Encode: EncodeToken consults the
+			// open list frame to suppress the per-element header a standalone
+			// tag would otherwise get.
+			if err := e.Encode(child); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(Token{Type: TagEnd})
+	case 10: // TAG_Compound
+		if err := e.EncodeToken(Token{Type: TagStart, Name: tag.Name, TagType: 10}); err != nil {
+			return err
+		}
+		for _, child := range tag.Compound {
+			if err := e.Encode(child); err != nil {
+				return err
+			}
+		}
+		return e.EncodeToken(Token{Type: TagEnd})
+	default:
+		return e.EncodeToken(Token{Type: Value, Name: tag.Name, TagType: tag.TagType, Value: tag.Value})
+	}
+}
+
+// readPayload reads one scalar or array tag's payload and returns it as a native
+// Go value: int8/int16/int32/int64/float32/float64 for the numeric tags, string
+// for TAG_String, and []int8/[]int32/[]int64 for the *_Array tags. TAG_List and
+// TAG_Compound are handled by Decoder.Token itself, not by readPayload.
+func readPayload(r io.Reader, enc Encoding, tagType byte) (interface{}, error) {
+	switch tagType {
+	case 1: // TAG_Byte
+		var v int8
+		err := binary.Read(r, enc.byteOrder, &v)
+		return v, err
+	case 2: // TAG_Short
+		var v int16
+		err := binary.Read(r, enc.byteOrder, &v)
+		return v, err
+	case 3: // TAG_Int
+		return readTagInt(r, enc)
+	case 4: // TAG_Long
+		return readTagLong(r, enc)
+	case 5: // TAG_Float
+		var v float32
+		err := binary.Read(r, enc.byteOrder, &v)
+		return v, err
+	case 6: // TAG_Double
+		var v float64
+		err := binary.Read(r, enc.byteOrder, &v)
+		return v, err
+	case 7: // TAG_Byte_Array
+		length, err := readContainerLen(r, enc)
+		if err != nil {
+			return nil, err
+		}
+		v := make([]int8, length)
+		for i := range v {
+			if err := binary.Read(r, enc.byteOrder, &v[i]); err != nil {
+				return nil, JsonParseError{"Error reading element of byte array", err}
+			}
+		}
+		return v, nil
+	case 8: // TAG_String
+		length, err := readStringLen(r, enc)
+		if err != nil {
+			return nil, err
+		}
+		b := make([]byte, length)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, JsonParseError{"Error reading string payload", err}
+		}
+		return string(b), nil
+	case 11: // TAG_Int_Array
+		length, err := readContainerLen(r, enc)
+		if err != nil {
+			return nil, err
+		}
+		v := make([]int32, length)
+		for i := range v {
+			n, err := readTagInt(r, enc)
+			if err != nil {
+				return nil, JsonParseError{"Error reading element of int array", err}
+			}
+			v[i] = n
+		}
+		return v, nil
+	case 12: // TAG_Long_Array
+		length, err := readContainerLen(r, enc)
+		if err != nil {
+			return nil, err
+		}
+		v := make([]int64, length)
+		for i := range v {
+			n, err := readTagLong(r, enc)
+			if err != nil {
+				return nil, JsonParseError{"Error reading element of long array", err}
+			}
+			v[i] = n
+		}
+		return v, nil
+	default:
+		return nil, JsonParseError{"readPayload: unrecognized tagType", nil}
+	}
+}
+
+// writePayloadValue writes a scalar or array payload given the native Go value
+// readPayload would have produced for that tagType.
+func writePayloadValue(w io.Writer, enc Encoding, tagType byte, value interface{}) error {
+	switch tagType {
+	case 3: // TAG_Int
+		v, ok := value.(int32)
+		if !ok {
+			return JsonParseError{"writePayloadValue: int value is not int32", nil}
+		}
+		if err := writeTagInt(w, enc, v); err != nil {
+			return JsonParseError{"Error writing int32 payload", err}
+		}
+		return nil
+	case 4: // TAG_Long
+		v, ok := value.(int64)
+		if !ok {
+			return JsonParseError{"writePayloadValue: long value is not int64", nil}
+		}
+		if err := writeTagLong(w, enc, v); err != nil {
+			return JsonParseError{"Error writing int64 payload", err}
+		}
+		return nil
+	case 7: // TAG_Byte_Array
+		v, ok := value.([]int8)
+		if !ok {
+			return JsonParseError{"writePayloadValue: byte array value is not []int8", nil}
+		}
+		if err := writeContainerLen(w, enc, len(v)); err != nil {
+			return JsonParseError{"Error writing byte array length", err}
+		}
+		return binary.Write(w, enc.byteOrder, v)
+	case 8: // TAG_String
+		s, ok := value.(string)
+		if !ok {
+			return JsonParseError{"writePayloadValue: string value is not a string", nil}
+		}
+		if err := writeStringLen(w, enc, len([]byte(s))); err != nil {
+			return JsonParseError{"Error writing string length", err}
+		}
+		return binary.Write(w, enc.byteOrder, []byte(s))
+	case 11: // TAG_Int_Array
+		v, ok := value.([]int32)
+		if !ok {
+			return JsonParseError{"writePayloadValue: int array value is not []int32", nil}
+		}
+		if err := writeContainerLen(w, enc, len(v)); err != nil {
+			return JsonParseError{"Error writing int array length", err}
+		}
+		for _, n := range v {
+			if err := writeTagInt(w, enc, n); err != nil {
+				return JsonParseError{"Error writing element of int array", err}
+			}
+		}
+		return nil
+	case 12: // TAG_Long_Array
+		v, ok := value.([]int64)
+		if !ok {
+			return JsonParseError{"writePayloadValue: long array value is not []int64", nil}
+		}
+		if err := writeContainerLen(w, enc, len(v)); err != nil {
+			return JsonParseError{"Error writing long array length", err}
+		}
+		for _, n := range v {
+			if err := writeTagLong(w, enc, n); err != nil {
+				return JsonParseError{"Error writing element of long array", err}
+			}
+		}
+		return nil
+	default: // the remaining scalar tags: binary.Write accepts int8/int16/float32/float64 directly
+		if err := binary.Write(w, enc.byteOrder, value); err != nil {
+			return JsonParseError{"Error writing scalar payload", err}
+		}
+		return nil
+	}
+}
+
+// Tag is an in-memory NBT tag tree, used by Decoder.Decode to hand callers a
+// complete value and available as a typed alternative to the untyped
+// map[string]interface{} shape Json2Nbt works with.
+type Tag struct {
+	Name        string
+	TagType     byte
+	Value       interface{} // scalar/array payload for every tagType except 9 and 10
+	TagListType byte        // element tagType, set when TagType == 9
+	List        []Tag       // children, set when TagType == 9
+	Compound    []Tag       // children, set when TagType == 10
+}
@@ -0,0 +1,55 @@
+package nbt2json
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncoderDecoderRoundTrip(t *testing.T) {
+	in := Tag{
+		Name:    "",
+		TagType: 10,
+		Compound: []Tag{
+			{Name: "Health", TagType: 5, Value: float32(20)},
+			{Name: "Name", TagType: 8, Value: "Steve"},
+			{Name: "Pos", TagType: 11, Value: []int32{1, 2, 3}},
+			{
+				Name:        "Items",
+				TagType:     9,
+				TagListType: 1,
+				List: []Tag{
+					{TagType: 1, Value: int8(1)},
+					{TagType: 1, Value: int8(2)},
+				},
+			},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf, EncodingJavaBig).Encode(in); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var out Tag
+	if err := NewDecoder(bytes.NewReader(buf.Bytes()), EncodingJavaBig).Decode(&out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	if len(out.Compound) != len(in.Compound) {
+		t.Fatalf("got %d compound children, want %d", len(out.Compound), len(in.Compound))
+	}
+	if out.Compound[0].Value.(float32) != 20 {
+		t.Fatalf("Health: got %v, want 20", out.Compound[0].Value)
+	}
+	if out.Compound[1].Value.(string) != "Steve" {
+		t.Fatalf("Name: got %v, want Steve", out.Compound[1].Value)
+	}
+	pos := out.Compound[2].Value.([]int32)
+	if len(pos) != 3 || pos[0] != 1 || pos[1] != 2 || pos[2] != 3 {
+		t.Fatalf("Pos: got %v, want [1 2 3]", pos)
+	}
+	items := out.Compound[3].List
+	if len(items) != 2 || items[0].Value.(int8) != 1 || items[1].Value.(int8) != 2 {
+		t.Fatalf("Items: got %v, want [1 2]", items)
+	}
+}